@@ -0,0 +1,101 @@
+package client
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// backoffPolicy computes retry delays using exponential backoff with full
+// jitter: delay = min(cap, base * 2^(attempt-1)) for the 1-indexed attempt
+// that just failed, sleep = rand(0, delay). Full
+// jitter spreads retries out so a collector outage doesn't turn into a
+// thundering herd when it recovers.
+type backoffPolicy struct {
+	base time.Duration
+	cap  time.Duration
+}
+
+func defaultBackoffPolicy() backoffPolicy {
+	return backoffPolicy{base: 500 * time.Millisecond, cap: 5 * time.Minute}
+}
+
+// next returns the time at which attempt (1-indexed, the attempt that just
+// failed) should be retried.
+func (b backoffPolicy) next(attempt int, after time.Time) time.Time {
+	delay := float64(b.base) * math.Pow(2, float64(attempt-1))
+	if max := float64(b.cap); delay > max {
+		delay = max
+	}
+
+	sleep := time.Duration(rand.Float64() * delay)
+
+	return after.Add(sleep)
+}
+
+// WithBackoff configures the base delay and cap used by the exponential
+// backoff-with-full-jitter retry policy. Defaults to a 500ms base and a
+// 5 minute cap.
+func WithBackoff(base, cap time.Duration) Option {
+	return func(o *Options) {
+		o.BackoffBase = base
+		o.BackoffCap = cap
+	}
+}
+
+func (o Options) backoffPolicy() backoffPolicy {
+	b := defaultBackoffPolicy()
+	if o.BackoffBase > 0 {
+		b.base = o.BackoffBase
+	}
+	if o.BackoffCap > 0 {
+		b.cap = o.BackoffCap
+	}
+
+	return b
+}
+
+// armRetryTimer resets timer to fire at the earliest NextAttempt currently
+// in the store, if any, so a retryable failure doesn't have to wait out a
+// full FlushInterval.
+func (t *Client) armRetryTimer(timer *time.Timer) {
+	d, ok := t.nextRetryDelay()
+	if !ok {
+		return
+	}
+
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+
+	timer.Reset(d)
+}
+
+func (t *Client) nextRetryDelay() (time.Duration, bool) {
+	var earliest time.Time
+
+	_ = t.store.Iterate(func(e *StoreEvent) bool {
+		if !e.Attempted || e.NextAttempt.IsZero() {
+			return true
+		}
+
+		if earliest.IsZero() || e.NextAttempt.Before(earliest) {
+			earliest = e.NextAttempt
+		}
+
+		return true
+	})
+
+	if earliest.IsZero() {
+		return 0, false
+	}
+
+	if d := time.Until(earliest); d > 0 {
+		return d, true
+	}
+
+	return 0, true
+}
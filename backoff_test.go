@@ -0,0 +1,63 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffPolicyNextBounds(t *testing.T) {
+	b := backoffPolicy{base: 100 * time.Millisecond, cap: time.Second}
+	now := time.Now()
+
+	for attempt := 1; attempt <= 10; attempt++ {
+		want := float64(b.base) * pow2(attempt-1)
+		if want > float64(b.cap) {
+			want = float64(b.cap)
+		}
+
+		for i := 0; i < 50; i++ {
+			got := b.next(attempt, now)
+
+			sleep := got.Sub(now)
+			if sleep < 0 {
+				t.Fatalf("attempt %d: next returned a time before now: %v", attempt, got)
+			}
+			if float64(sleep) > want {
+				t.Fatalf("attempt %d: sleep %v exceeds max delay %v", attempt, sleep, time.Duration(want))
+			}
+		}
+	}
+}
+
+func TestBackoffPolicyNextRespectsCap(t *testing.T) {
+	b := backoffPolicy{base: time.Second, cap: 2 * time.Second}
+	now := time.Now()
+
+	// A large attempt count would blow well past the cap without clamping.
+	for i := 0; i < 50; i++ {
+		got := b.next(20, now)
+		if sleep := got.Sub(now); sleep > b.cap {
+			t.Fatalf("sleep %v exceeds cap %v", sleep, b.cap)
+		}
+	}
+}
+
+func pow2(n int) float64 {
+	v := 1.0
+	for i := 0; i < n; i++ {
+		v *= 2
+	}
+
+	return v
+}
+
+func TestDefaultBackoffPolicy(t *testing.T) {
+	b := defaultBackoffPolicy()
+
+	if b.base != 500*time.Millisecond {
+		t.Errorf("base = %v, want 500ms", b.base)
+	}
+	if b.cap != 5*time.Minute {
+		t.Errorf("cap = %v, want 5m", b.cap)
+	}
+}
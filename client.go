@@ -2,8 +2,9 @@ package client
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/blushft/jitsuclient/event"
@@ -14,12 +15,17 @@ import (
 )
 
 type Client struct {
-	options Options
-	httpc   *resty.Client
-	store   Store
+	options  Options
+	httpc    *resty.Client
+	store    Store
+	deadline *deadline
 
-	q  chan *event.Event
-	cl chan struct{}
+	q    chan *event.Event
+	cl   chan struct{}
+	done chan struct{}
+
+	shutdownCtx    context.Context
+	shutdownCancel context.CancelFunc
 
 	copts []event.Option
 }
@@ -27,19 +33,48 @@ type Client struct {
 func New(opts ...Option) (*Client, error) {
 	options := defaultOptions(opts...)
 
+	if options.Logger == nil {
+		options.Logger = NewStdLogger()
+	}
+
+	if options.DeadLetterSink == nil {
+		options.DeadLetterSink = NewFileDeadLetterSink("deadletter.jsonl")
+	}
+
 	httpc := resty.New().SetHostURL(options.CollectorURL)
-	store, err := NewMemStore()
-	if err != nil {
-		return nil, err
+
+	store := options.Store
+	if options.StoreFactory != nil {
+		s, err := options.StoreFactory(&options)
+		if err != nil {
+			return nil, fmt.Errorf("error constructing store: %w", err)
+		}
+
+		store = s
 	}
 
+	if store == nil {
+		s, err := NewMemStore()
+		if err != nil {
+			return nil, err
+		}
+
+		store = s
+	}
+
+	shutdownCtx, shutdownCancel := context.WithCancel(context.Background())
+
 	t := &Client{
-		options: options,
-		httpc:   httpc,
-		store:   store,
-		q:       make(chan *event.Event, options.QueueBuffer),
-		cl:      make(chan struct{}),
-		copts:   options.EventOptions(),
+		options:        options,
+		httpc:          httpc,
+		store:          store,
+		deadline:       newDeadline(),
+		q:              make(chan *event.Event, options.QueueBuffer),
+		cl:             make(chan struct{}),
+		done:           make(chan struct{}),
+		shutdownCtx:    shutdownCtx,
+		shutdownCancel: shutdownCancel,
+		copts:          options.EventOptions(),
 	}
 
 	go t.run()
@@ -61,9 +96,7 @@ func (t *Client) SetGroup(grp *contexts.Group) *Client {
 }
 
 func (t *Client) Queue(evt *event.Event) {
-	evt.Apply(t.eventOptions()...)
-
-	t.q <- evt
+	_ = t.QueueCtx(context.Background(), evt)
 }
 
 func (t *Client) Action(a *contexts.Action, opts ...event.Option) {
@@ -103,20 +136,11 @@ func (t *Client) Timing(te *contexts.Timing, opts ...event.Option) {
 }
 
 func (t *Client) Flush() {
-	c, err := t.emit()
-	if err != nil {
-		log.Printf("error emitting events: %v\n", err)
-	}
-
-	if c > 0 && t.options.Debug {
-		log.Printf("emitted %d events", c)
-	}
+	_ = t.FlushCtx(context.Background())
 }
 
 func (t *Client) Close() {
-	close(t.cl)
-
-	t.Flush()
+	_ = t.CloseCtx(context.Background())
 }
 
 func (t *Client) eventOptions(opts ...event.Option) []event.Option {
@@ -129,67 +153,129 @@ func (t *Client) eventOptions(opts ...event.Option) []event.Option {
 }
 
 func (t *Client) run() {
+	defer close(t.done)
+
 	tick := time.NewTicker(t.options.FlushInterval)
 	defer tick.Stop()
 
+	retryTimer := time.NewTimer(t.options.FlushInterval)
+	defer retryTimer.Stop()
+
+	if n := t.store.Count(); n > 0 {
+		t.options.Logger.Infof("replaying %d events left over from a prior run", n)
+		_ = t.FlushCtx(t.shutdownCtx)
+	}
+
 	for {
 		select {
 		case e := <-t.q:
 			if t.options.Strict {
 				if !e.Validate() {
-					log.Printf("event failed validation")
+					t.options.Logger.Warnf("event failed validation")
 					continue
 				}
 			}
 
 			if err := t.store.Set(e); err != nil {
-				log.Printf("error storing event: %s\n", err.Error())
+				t.options.Logger.Errorf("error storing event: %s", err.Error())
 				continue
 			}
 
-			if t.store.Count() >= t.options.FlushCount {
-				t.Flush()
+			t.options.Metrics.recordStoreDepth(t.store.Count())
+
+			overCount := t.store.Count() >= t.options.FlushCount
+			overBytes := t.options.FlushBytes > 0 && t.store.Bytes() >= t.options.FlushBytes
+
+			if overCount || overBytes {
+				_ = t.FlushCtx(t.shutdownCtx)
+				t.armRetryTimer(retryTimer)
 			}
 		case <-tick.C:
-			t.Flush()
+			_ = t.FlushCtx(t.shutdownCtx)
+			t.armRetryTimer(retryTimer)
+		case <-retryTimer.C:
+			_ = t.FlushCtx(t.shutdownCtx)
+			t.armRetryTimer(retryTimer)
 		case <-t.cl:
 			return
 		}
 	}
 }
 
-func (t *Client) emit() (int, error) {
+// maxScanPerFlush bounds how many pending events a single emit/emitBulk
+// call pulls out of the store. A durable store backlogged from a
+// collector outage can hold far more events than comfortably fit in
+// memory at once - scanPending stops collecting once it hits this many,
+// leaving the rest for the next flush tick.
+const maxScanPerFlush = 1000
+
+// scanPending collects up to limit events eligible to send right now (not
+// yet attempted, or past their backoff NextAttempt) by scanning the store
+// with Iterate rather than GetAll, stopping as soon as the limit is hit.
+// The bolt and sqlite backends stream their Iterate scan, so stopping
+// early also means they stop paying the per-event deserialization cost
+// for whatever's left over the limit; the file store already holds every
+// event in memory.
+//
+// Matching and mutation happen in two separate passes - this only
+// collects - because every Store holds its lock (or, for boltStore, a
+// db.View transaction, and for sqliteStore an open query) across the
+// Iterate callback, and the
+// preSend/send/sendFailed callers of this function mutate the store
+// (Update/Remove); doing that from inside the callback self-deadlocks.
+func (t *Client) scanPending(now time.Time, limit int) ([]*StoreEvent, error) {
+	var pending []*StoreEvent
+
+	err := t.store.Iterate(func(e *StoreEvent) bool {
+		if e.Attempted && !e.NextAttempt.IsZero() && e.NextAttempt.After(now) {
+			return true
+		}
+
+		pending = append(pending, e)
+
+		return len(pending) < limit
+	})
+
+	return pending, err
+}
+
+func (t *Client) emit(ctx context.Context) (int, error) {
 	if t.store.Count() == 0 {
 		return 0, nil
 	}
 
 	if t.options.Bulk {
-		return t.emitBulk()
+		return t.emitBulk(ctx)
 	}
 
-	evts, err := t.store.GetAll()
+	all, err := t.scanPending(time.Now(), maxScanPerFlush)
 	if err != nil {
 		return 0, err
 	}
 
 	i := 0
-	for _, e := range evts {
+
+	for _, e := range all {
+		if !t.preSend(ctx, e) {
+			continue
+		}
+
 		var rm bool
-		var err error
+		var sfErr error
 
-		if serr := t.send(e.Event); serr == nil {
+		if serr := t.send(ctx, e.ID, e.Event); serr == nil {
 			i++
 			rm = true
 		} else {
-			rm, err = t.sendFailed(e, serr)
-			if err != nil {
-				log.Println(err.Error())
+			rm, sfErr = t.sendFailed(e, serr)
+			if sfErr != nil {
+				t.options.Logger.Errorf("%s", sfErr.Error())
 			}
 		}
 
 		if rm {
 			if err := t.store.Remove(e); err != nil {
-				log.Printf("error removing event: %v", err)
+				t.options.Logger.Errorf("error removing event: %v", err)
 			}
 		}
 	}
@@ -197,14 +283,44 @@ func (t *Client) emit() (int, error) {
 	return i, nil
 }
 
-func (t *Client) emitBulk() (int, error) {
-	start := time.Now()
-
-	evts, err := t.store.GetAll()
+func (t *Client) emitBulk(ctx context.Context) (int, error) {
+	evts, err := t.scanPending(time.Now(), maxScanPerFlush)
 	if err != nil {
 		return 0, err
 	}
 
+	filtered := evts[:0]
+	for _, e := range evts {
+		if t.preSend(ctx, e) {
+			filtered = append(filtered, e)
+		}
+	}
+	evts = filtered
+
+	if len(evts) == 0 {
+		return 0, nil
+	}
+
+	i := 0
+
+	for _, chunk := range chunkByBytes(evts, t.options.MaxBatchBytes) {
+		n, err := t.emitBulkChunk(ctx, chunk)
+		i += n
+
+		if err != nil {
+			t.options.Logger.Errorf("error emitting bulk chunk: %v", err)
+		}
+	}
+
+	return i, nil
+}
+
+// emitBulkChunk POSTs a single chunk of evts, retrying each through
+// sendFailed independently so a partial batch failure doesn't re-send
+// chunks that already succeeded.
+func (t *Client) emitBulkChunk(ctx context.Context, evts []*StoreEvent) (int, error) {
+	start := time.Now()
+
 	bulk := make([][]byte, len(evts))
 	for c, e := range evts {
 		bulk[c] = e.Event
@@ -212,27 +328,45 @@ func (t *Client) emitBulk() (int, error) {
 
 	body := bytes.Join(bulk, []byte("\n"))
 
+	encoded, encoding, err := compress(t.options.Compression, body)
+	if err != nil {
+		return 0, err
+	}
+
 	headers := t.options.clientHeaders()
+	if encoding != "" {
+		headers["Content-Encoding"] = encoding
+	}
+
+	ctx, cancel := t.deadline.context(ctx, t.options.DefaultTimeout)
+	defer cancel()
 
 	req := t.httpc.R().
-		SetFileReader("file", "file", bytes.NewReader(body)).
+		SetContext(ctx).
+		SetFileReader("file", "file", bytes.NewReader(encoded)).
 		SetHeaders(headers).
 		SetQueryParams(t.options.apiQueryParams())
 
-	resp, err := req.Post(t.options.apiPath())
+	resp, postErr := req.Post(t.options.apiPath())
 
-	failed := err != nil || resp.StatusCode() > 299
-	if t.options.Debug {
-		if err != nil {
-			log.Printf("error sending bulk: %v", err)
+	var bulkErr error
+	if postErr != nil {
+		bulkErr = &SendError{Err: postErr}
+	} else if resp.StatusCode() > 299 {
+		bulkErr = &SendError{
+			StatusCode: resp.StatusCode(),
+			RetryAfter: parseRetryAfter(resp.Header().Get("Retry-After")),
 		}
+	}
 
-		if resp.StatusCode() > 299 {
-			log.Printf("http response: code=%d body=%s", resp.StatusCode(), resp.Body())
-		}
+	bulkLog := t.options.Logger.WithFields(map[string]interface{}{"batch_size": len(evts)})
+	if t.options.Debug && bulkErr != nil {
+		bulkLog.Errorf("error sending bulk: %v", bulkErr)
 	}
 
-	t.logDebug("bulk send complete: dur=%dms", time.Since(start).Milliseconds())
+	bulkLog.WithFields(map[string]interface{}{
+		"duration_ms": time.Since(start).Milliseconds(),
+	}).Debugf("bulk send complete")
 
 	i := 0
 
@@ -240,19 +374,20 @@ func (t *Client) emitBulk() (int, error) {
 		var rm bool
 		var err error
 
-		if failed {
-			rm, err = t.sendFailed(e, err)
+		if bulkErr != nil {
+			rm, err = t.sendFailed(e, bulkErr)
 			if err != nil {
-				log.Println(err.Error())
+				t.options.Logger.Errorf("%s", err.Error())
 			}
 		} else {
 			i++
 			rm = true
+			t.options.Metrics.recordSent(time.Since(start))
 		}
 
 		if rm {
 			if err := t.store.Remove(e); err != nil {
-				log.Printf("error removing event: %v", err)
+				t.options.Logger.Errorf("error removing event: %v", err)
 			}
 		}
 	}
@@ -260,23 +395,41 @@ func (t *Client) emitBulk() (int, error) {
 	return i, nil
 }
 
-func (t *Client) send(e []byte) error {
+func (t *Client) send(ctx context.Context, id string, e []byte) error {
 	start := time.Now()
 	headers := t.options.clientHeaders()
 
+	ctx, cancel := t.deadline.context(ctx, t.options.DefaultTimeout)
+	defer cancel()
+
 	resp, err := t.httpc.R().
+		SetContext(ctx).
 		SetBody(e).
 		SetHeaders(headers).
 		SetQueryParams(t.options.apiQueryParams()).
 		Post(t.options.apiPath())
 
+	if err != nil {
+		return &SendError{Err: err}
+	}
+
 	if resp.StatusCode() > 299 {
-		return fmt.Errorf("send failed with status code %d", resp.StatusCode())
+		return &SendError{
+			StatusCode: resp.StatusCode(),
+			RetryAfter: parseRetryAfter(resp.Header().Get("Retry-After")),
+		}
+	}
+
+	if t.options.Debug {
+		t.options.Logger.WithFields(map[string]interface{}{
+			"event_id":    id,
+			"duration_ms": time.Since(start).Milliseconds(),
+		}).Debugf("event send complete")
 	}
 
-	t.logDebug("event send complete: dur=%dms", time.Since(start).Milliseconds())
+	t.options.Metrics.recordSent(time.Since(start))
 
-	return err
+	return nil
 }
 
 func (t *Client) sendFailed(e *StoreEvent, sendErr error) (bool, error) {
@@ -286,24 +439,45 @@ func (t *Client) sendFailed(e *StoreEvent, sendErr error) (bool, error) {
 	e.Attempts++
 	e.LastAttempt = time.Now()
 
+	eventLog := t.options.Logger.WithFields(map[string]interface{}{
+		"event_id": e.ID,
+		"attempt":  e.Attempts,
+	})
+
 	if t.options.Debug {
-		log.Printf("event failed to send: %v", sendErr)
+		eventLog.Warnf("event failed to send: %v", sendErr)
 	}
 
-	if t.options.MaxRetries > 0 && e.Attempts > t.options.MaxRetries {
+	retryable := true
+	var retryAfter time.Duration
+
+	var serr *SendError
+	if errors.As(sendErr, &serr) {
+		retryable = serr.Retryable()
+		retryAfter = serr.RetryAfter
+	}
+
+	exceeded := t.options.MaxRetries > 0 && e.Attempts > t.options.MaxRetries
+
+	if !retryable || exceeded {
 		rm = true
-	} else {
-		if err := t.store.Update(e); err != nil {
-			return rm, fmt.Errorf("error updating event: %w", err)
+
+		if t.options.DeadLetterSink != nil {
+			t.options.DeadLetterSink.Dead(e, sendErr)
 		}
+
+		return rm, nil
 	}
 
-	return rm, nil
-}
+	if retryAfter > 0 {
+		e.NextAttempt = time.Now().Add(retryAfter)
+	} else {
+		e.NextAttempt = t.options.backoffPolicy().next(e.Attempts, time.Now())
+	}
 
-func (c *Client) logDebug(msg string, args ...interface{}) {
-	if c.options.Debug {
-		log.Printf(msg, args...)
+	if err := t.store.Update(e); err != nil {
+		return rm, fmt.Errorf("error updating event: %w", err)
 	}
 
+	return rm, nil
 }
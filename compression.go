@@ -0,0 +1,57 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// CompressionType selects the Content-Encoding applied to bulk upload
+// bodies.
+type CompressionType int
+
+const (
+	CompressionNone CompressionType = iota
+	CompressionGzip
+	CompressionZstd
+)
+
+// WithCompression sets the compression applied to bulk upload bodies.
+// Defaults to CompressionNone.
+func WithCompression(c CompressionType) Option {
+	return func(o *Options) {
+		o.Compression = c
+	}
+}
+
+// compress encodes body per c, returning the encoded bytes and the
+// Content-Encoding header value to send alongside them ("" for
+// CompressionNone, in which case body is returned unmodified).
+func compress(c CompressionType, body []byte) ([]byte, string, error) {
+	switch c {
+	case CompressionGzip:
+		var buf bytes.Buffer
+
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(body); err != nil {
+			return nil, "", fmt.Errorf("error gzip-compressing body: %w", err)
+		}
+		if err := w.Close(); err != nil {
+			return nil, "", fmt.Errorf("error closing gzip writer: %w", err)
+		}
+
+		return buf.Bytes(), "gzip", nil
+	case CompressionZstd:
+		enc, err := zstd.NewWriter(nil)
+		if err != nil {
+			return nil, "", fmt.Errorf("error creating zstd writer: %w", err)
+		}
+		defer enc.Close()
+
+		return enc.EncodeAll(body, nil), "zstd", nil
+	default:
+		return body, "", nil
+	}
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestCompressNone(t *testing.T) {
+	body := []byte("hello world")
+
+	got, encoding, err := compress(CompressionNone, body)
+	if err != nil {
+		t.Fatalf("compress: %v", err)
+	}
+	if encoding != "" {
+		t.Errorf("encoding = %q, want \"\"", encoding)
+	}
+	if !bytes.Equal(got, body) {
+		t.Errorf("got %q, want %q unchanged", got, body)
+	}
+}
+
+func TestCompressGzipRoundTrip(t *testing.T) {
+	body := []byte(`{"event":"test"}` + "\n" + `{"event":"test2"}`)
+
+	encoded, encoding, err := compress(CompressionGzip, body)
+	if err != nil {
+		t.Fatalf("compress: %v", err)
+	}
+	if encoding != "gzip" {
+		t.Errorf("encoding = %q, want \"gzip\"", encoding)
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(encoded))
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	defer r.Close()
+
+	decoded, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("reading gzip stream: %v", err)
+	}
+
+	if !bytes.Equal(decoded, body) {
+		t.Errorf("round trip = %q, want %q", decoded, body)
+	}
+}
+
+func TestCompressZstdRoundTrip(t *testing.T) {
+	body := []byte(`{"event":"test"}` + "\n" + `{"event":"test2"}`)
+
+	encoded, encoding, err := compress(CompressionZstd, body)
+	if err != nil {
+		t.Fatalf("compress: %v", err)
+	}
+	if encoding != "zstd" {
+		t.Errorf("encoding = %q, want \"zstd\"", encoding)
+	}
+
+	dec, err := zstd.NewReader(nil)
+	if err != nil {
+		t.Fatalf("zstd.NewReader: %v", err)
+	}
+	defer dec.Close()
+
+	decoded, err := dec.DecodeAll(encoded, nil)
+	if err != nil {
+		t.Fatalf("decoding zstd stream: %v", err)
+	}
+
+	if !bytes.Equal(decoded, body) {
+		t.Errorf("round trip = %q, want %q", decoded, body)
+	}
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+
+	"github.com/blushft/jitsuclient/event"
+	"github.com/blushft/jitsuclient/event/contexts"
+	"github.com/blushft/jitsuclient/event/events"
+)
+
+// QueueCtx behaves like Queue but aborts the channel send with ctx.Err()
+// if ctx (or the client's own shutdown context) fires before the event is
+// accepted onto the internal queue.
+func (t *Client) QueueCtx(ctx context.Context, evt *event.Event) error {
+	evt.Apply(t.eventOptions()...)
+
+	if err := t.runMiddleware(ctx, evt); err != nil {
+		return err
+	}
+
+	select {
+	case t.q <- evt:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.shutdownCtx.Done():
+		return t.shutdownCtx.Err()
+	}
+}
+
+func (t *Client) ActionCtx(ctx context.Context, a *contexts.Action, opts ...event.Option) error {
+	return t.QueueCtx(ctx, events.Action(a, opts...))
+}
+
+func (t *Client) TrackCtx(ctx context.Context, evt string, opts ...event.Option) error {
+	return t.QueueCtx(ctx, events.Track(evt, opts...))
+}
+
+func (t *Client) IdentifyCtx(ctx context.Context, u *contexts.User, opts ...event.Option) error {
+	return t.QueueCtx(ctx, events.Identify(u, opts...))
+}
+
+func (t *Client) AliasCtx(ctx context.Context, alias *contexts.Alias, usr *contexts.User, opts ...event.Option) error {
+	return t.QueueCtx(ctx, events.Alias(alias, usr, opts...))
+}
+
+func (t *Client) PageCtx(ctx context.Context, page *contexts.Page, opts ...event.Option) error {
+	return t.QueueCtx(ctx, events.Pageview(page, opts...))
+}
+
+func (t *Client) ScreenCtx(ctx context.Context, screen *contexts.Screen, opts ...event.Option) error {
+	return t.QueueCtx(ctx, events.Screen(screen, opts...))
+}
+
+func (t *Client) SessionCtx(ctx context.Context, sess *contexts.Session, opts ...event.Option) error {
+	return t.QueueCtx(ctx, events.Session(sess, opts...))
+}
+
+func (t *Client) GroupCtx(ctx context.Context, g *contexts.Group, u *contexts.User, opts ...event.Option) error {
+	return t.QueueCtx(ctx, events.Group(g, u, opts...))
+}
+
+func (t *Client) TimingCtx(ctx context.Context, te *contexts.Timing, opts ...event.Option) error {
+	return t.QueueCtx(ctx, events.Timing(te, opts...))
+}
+
+// FlushCtx emits queued events, bounding the HTTP round-trip(s) by ctx.
+func (t *Client) FlushCtx(ctx context.Context) error {
+	c, err := t.emit(ctx)
+	t.options.Metrics.recordStoreDepth(t.store.Count())
+	if err != nil {
+		t.options.Logger.Errorf("error emitting events: %v", err)
+		return err
+	}
+
+	if c > 0 && t.options.Debug {
+		t.options.Logger.Infof("emitted %d events", c)
+	}
+
+	return nil
+}
+
+// CloseCtx stops the run loop, cancels the client's internal shutdown
+// context so any in-flight sends abort, waits for run() to quiesce, and
+// only then performs a final drain-and-flush bounded by ctx. Waiting for
+// run() to actually stop (rather than just signaling it to) matters: emit
+// mutates the store, and running it concurrently with a still-in-flight
+// tick/queue-triggered flush would send the same stored event twice.
+func (t *Client) CloseCtx(ctx context.Context) error {
+	t.shutdownCancel()
+	close(t.cl)
+
+	select {
+	case <-t.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+drain:
+	for {
+		select {
+		case e := <-t.q:
+			if t.options.Strict && !e.Validate() {
+				continue
+			}
+
+			if err := t.store.Set(e); err != nil {
+				t.options.Logger.Errorf("error storing event: %s", err.Error())
+			}
+		default:
+			break drain
+		}
+	}
+
+	return t.FlushCtx(ctx)
+}
@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/blushft/jitsuclient/event"
+	"github.com/blushft/jitsuclient/event/events"
+)
+
+func TestQueueCtxCancelledContext(t *testing.T) {
+	c := &Client{
+		q:           make(chan *event.Event),
+		shutdownCtx: context.Background(),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Nothing ever reads c.q, so the send blocks forever on its own - the
+	// only way this returns is via the already-cancelled ctx.Done() case.
+	if err := c.QueueCtx(ctx, events.Track("cancelled")); !errors.Is(err, context.Canceled) {
+		t.Fatalf("QueueCtx with cancelled ctx = %v, want context.Canceled", err)
+	}
+}
+
+func TestQueueCtxAcceptsBeforeCancel(t *testing.T) {
+	c := &Client{
+		q:           make(chan *event.Event, 1),
+		shutdownCtx: context.Background(),
+	}
+
+	if err := c.QueueCtx(context.Background(), events.Track("ok")); err != nil {
+		t.Fatalf("QueueCtx: %v", err)
+	}
+
+	select {
+	case <-c.q:
+	default:
+		t.Fatal("QueueCtx did not enqueue the event onto c.q")
+	}
+}
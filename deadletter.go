@@ -0,0 +1,70 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// DeadLetterSink receives events that have exceeded MaxRetries instead of
+// having them silently dropped.
+type DeadLetterSink interface {
+	Dead(e *StoreEvent, cause error)
+}
+
+// WithDeadLetterSink sets the DeadLetterSink used for events that exceed
+// MaxRetries. Defaults to a fileDeadLetterSink writing to "deadletter.jsonl".
+func WithDeadLetterSink(s DeadLetterSink) Option {
+	return func(o *Options) {
+		o.DeadLetterSink = s
+	}
+}
+
+// fileDeadLetterSink appends dead events as JSONL records to a file.
+type fileDeadLetterSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+type deadLetterRecord struct {
+	ID       string `json:"id"`
+	Event    []byte `json:"event"`
+	Attempts int    `json:"attempts"`
+	Cause    string `json:"cause"`
+}
+
+// NewFileDeadLetterSink returns a DeadLetterSink that appends JSONL records
+// to path, creating it if necessary.
+func NewFileDeadLetterSink(path string) DeadLetterSink {
+	return &fileDeadLetterSink{path: path}
+}
+
+func (f *fileDeadLetterSink) Dead(e *StoreEvent, cause error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	rec := deadLetterRecord{
+		ID:       e.ID,
+		Event:    e.Event,
+		Attempts: e.Attempts,
+	}
+	if cause != nil {
+		rec.Cause = cause.Error()
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		fmt.Fprintf(os.Stderr, "jitsuclient: error writing dead letter: %v\n", err)
+	}
+}
@@ -0,0 +1,55 @@
+package client
+
+// WithFlushBytes sets a byte threshold: once the cumulative serialized
+// size of stored events reaches n, the run loop flushes immediately
+// instead of waiting for FlushCount or FlushInterval. Zero (the default)
+// disables byte-based flushing.
+func WithFlushBytes(n int64) Option {
+	return func(o *Options) {
+		o.FlushBytes = n
+	}
+}
+
+// WithMaxBatchBytes bounds a single bulk POST to roughly n bytes of
+// serialized events. emitBulk splits a flush larger than this into
+// multiple requests, each retried independently through sendFailed so a
+// partial batch failure doesn't re-send chunks that already succeeded.
+// Zero (the default) disables chunking.
+func WithMaxBatchBytes(n int64) Option {
+	return func(o *Options) {
+		o.MaxBatchBytes = n
+	}
+}
+
+// chunkByBytes splits evts into groups whose summed StoreEvent.Event
+// length is at most maxBytes each. A single event larger than maxBytes
+// still gets its own chunk rather than being dropped. maxBytes <= 0
+// disables chunking and returns evts as a single group.
+func chunkByBytes(evts []*StoreEvent, maxBytes int64) [][]*StoreEvent {
+	if maxBytes <= 0 || len(evts) == 0 {
+		return [][]*StoreEvent{evts}
+	}
+
+	var chunks [][]*StoreEvent
+	var cur []*StoreEvent
+	var curBytes int64
+
+	for _, e := range evts {
+		sz := int64(len(e.Event))
+
+		if len(cur) > 0 && curBytes+sz > maxBytes {
+			chunks = append(chunks, cur)
+			cur = nil
+			curBytes = 0
+		}
+
+		cur = append(cur, e)
+		curBytes += sz
+	}
+
+	if len(cur) > 0 {
+		chunks = append(chunks, cur)
+	}
+
+	return chunks
+}
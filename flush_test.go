@@ -0,0 +1,66 @@
+package client
+
+import "testing"
+
+func chunkSizes(chunks [][]*StoreEvent) []int {
+	sizes := make([]int, len(chunks))
+	for i, c := range chunks {
+		sizes[i] = len(c)
+	}
+
+	return sizes
+}
+
+func eventsOfSizes(sizes ...int) []*StoreEvent {
+	evts := make([]*StoreEvent, len(sizes))
+	for i, n := range sizes {
+		evts[i] = &StoreEvent{ID: string(rune('a' + i)), Event: make([]byte, n)}
+	}
+
+	return evts
+}
+
+func TestChunkByBytesDisabled(t *testing.T) {
+	evts := eventsOfSizes(10, 20, 30)
+
+	chunks := chunkByBytes(evts, 0)
+	if len(chunks) != 1 || len(chunks[0]) != 3 {
+		t.Fatalf("chunkByBytes(maxBytes=0) = %v, want a single chunk of 3", chunkSizes(chunks))
+	}
+}
+
+func TestChunkByBytesSplits(t *testing.T) {
+	evts := eventsOfSizes(40, 40, 40, 10)
+
+	chunks := chunkByBytes(evts, 100)
+
+	got := chunkSizes(chunks)
+	want := []int{2, 2}
+	if len(got) != len(want) {
+		t.Fatalf("chunkByBytes split into %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("chunkByBytes split into %v, want %v", got, want)
+		}
+	}
+}
+
+func TestChunkByBytesOversizedEventGetsOwnChunk(t *testing.T) {
+	evts := eventsOfSizes(5, 200, 5)
+
+	chunks := chunkByBytes(evts, 100)
+
+	got := chunkSizes(chunks)
+	want := []int{1, 1, 1}
+	if len(got) != len(want) || got[0] != 1 || got[1] != 1 || got[2] != 1 {
+		t.Fatalf("chunkByBytes(oversized) = %v, want %v (one event per chunk)", got, want)
+	}
+}
+
+func TestChunkByBytesEmpty(t *testing.T) {
+	chunks := chunkByBytes(nil, 100)
+	if len(chunks) != 1 || len(chunks[0]) != 0 {
+		t.Fatalf("chunkByBytes(nil) = %v, want a single empty chunk", chunkSizes(chunks))
+	}
+}
@@ -0,0 +1,81 @@
+package client
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+// Logger is the diagnostics sink used throughout the client. Implementations
+// backed by structured loggers (logrus, zap, ...) can use WithFields to
+// attach event-scoped context instead of parsing printf-style messages.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+	WithFields(fields map[string]interface{}) Logger
+}
+
+// stdLogger is the default Logger, backed by the standard library log
+// package. Fields are rendered as trailing key=value pairs.
+type stdLogger struct {
+	l      *log.Logger
+	fields map[string]interface{}
+}
+
+// NewStdLogger returns a Logger that writes leveled, stdlib-formatted lines
+// to stderr.
+func NewStdLogger() Logger {
+	return &stdLogger{l: log.New(os.Stderr, "", log.LstdFlags)}
+}
+
+func (s *stdLogger) Debugf(format string, args ...interface{}) { s.logf("DEBUG", format, args...) }
+func (s *stdLogger) Infof(format string, args ...interface{})  { s.logf("INFO", format, args...) }
+func (s *stdLogger) Warnf(format string, args ...interface{})  { s.logf("WARN", format, args...) }
+func (s *stdLogger) Errorf(format string, args ...interface{}) { s.logf("ERROR", format, args...) }
+
+func (s *stdLogger) WithFields(fields map[string]interface{}) Logger {
+	merged := make(map[string]interface{}, len(s.fields)+len(fields))
+	for k, v := range s.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	return &stdLogger{l: s.l, fields: merged}
+}
+
+func (s *stdLogger) logf(level, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	for k, v := range s.fields {
+		msg = fmt.Sprintf("%s %s=%v", msg, k, v)
+	}
+
+	s.l.Printf("[%s] %s", level, msg)
+}
+
+// noopLogger discards everything. It's the right default for embedders that
+// don't want the client's internal diagnostics.
+type noopLogger struct{}
+
+// NewNoopLogger returns a Logger that discards all messages.
+func NewNoopLogger() Logger {
+	return noopLogger{}
+}
+
+func (noopLogger) Debugf(format string, args ...interface{}) {}
+func (noopLogger) Infof(format string, args ...interface{})  {}
+func (noopLogger) Warnf(format string, args ...interface{})  {}
+func (noopLogger) Errorf(format string, args ...interface{}) {}
+
+func (noopLogger) WithFields(fields map[string]interface{}) Logger { return noopLogger{} }
+
+// WithLogger sets the Logger used for all client diagnostics. Defaults to
+// NewStdLogger.
+func WithLogger(l Logger) Option {
+	return func(o *Options) {
+		o.Logger = l
+	}
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"bytes"
+	"log"
+	"testing"
+)
+
+func TestStdLoggerWithFieldsAppendsKeyValuePairs(t *testing.T) {
+	var buf bytes.Buffer
+	l := &stdLogger{l: log.New(&buf, "", 0)}
+
+	l.WithFields(map[string]interface{}{"id": "abc"}).Infof("sent")
+
+	got := buf.String()
+	if !bytes.Contains([]byte(got), []byte("[INFO] sent id=abc")) {
+		t.Fatalf("log line = %q, want it to contain %q", got, "[INFO] sent id=abc")
+	}
+}
+
+func TestStdLoggerWithFieldsMerges(t *testing.T) {
+	var buf bytes.Buffer
+	l := &stdLogger{l: log.New(&buf, "", 0)}
+
+	base := l.WithFields(map[string]interface{}{"a": 1})
+	base.WithFields(map[string]interface{}{"b": 2}).Warnf("msg")
+
+	got := buf.String()
+	if !bytes.Contains([]byte(got), []byte("a=1")) || !bytes.Contains([]byte(got), []byte("b=2")) {
+		t.Fatalf("log line = %q, want it to contain both a=1 and b=2", got)
+	}
+}
+
+func TestNoopLoggerDiscardsEverything(t *testing.T) {
+	l := NewNoopLogger()
+
+	// None of these should panic, and WithFields should keep returning a
+	// Logger that also discards.
+	l.Debugf("x")
+	l.Infof("x")
+	l.Warnf("x")
+	l.Errorf("x")
+	l.WithFields(map[string]interface{}{"k": "v"}).Infof("x")
+}
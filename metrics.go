@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/blushft/jitsuclient/event"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metrics holds the Prometheus instruments recorded for one Client. It's
+// only ever constructed by WithMetrics, so a Client that doesn't opt in
+// carries a nil *metrics: recordSent and recordStoreDepth are no-ops
+// against that, meaning recording costs nothing and touches no registry
+// unless a caller asks for it. queueMiddleware is only built by
+// WithMetrics, on a non-nil *metrics.
+type metrics struct {
+	eventsQueued prometheus.Counter
+	eventsSent   prometheus.Counter
+	sendDuration prometheus.Histogram
+	storeDepth   prometheus.Gauge
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		eventsQueued: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "jitsu_events_queued_total",
+			Help: "Total number of events accepted onto the client queue.",
+		}),
+		eventsSent: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "jitsu_events_sent_total",
+			Help: "Total number of events successfully sent to the collector.",
+		}),
+		sendDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name: "jitsu_send_duration_seconds",
+			Help: "Duration of individual event send round-trips.",
+		}),
+		storeDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "jitsu_store_depth",
+			Help: "Number of events currently held in the store.",
+		}),
+	}
+
+	reg.MustRegister(m.eventsQueued, m.eventsSent, m.sendDuration, m.storeDepth)
+
+	return m
+}
+
+// WithMetrics enables Prometheus instrumentation for queued, sent, and
+// stored events, registering jitsu_events_queued_total,
+// jitsu_events_sent_total, jitsu_send_duration_seconds, and
+// jitsu_store_depth against reg. Pass prometheus.DefaultRegisterer for the
+// global registry, or a dedicated prometheus.NewRegistry() to keep a
+// client's metrics isolated - a process constructing more than one Client
+// against the same reg will collide on registration. Metrics are otherwise
+// never recorded: importing jitsuclient has no effect on any registry
+// until this option is used.
+func WithMetrics(reg prometheus.Registerer) Option {
+	return func(o *Options) {
+		m := newMetrics(reg)
+		o.Metrics = m
+		o.Middleware = append(o.Middleware, m.queueMiddleware())
+	}
+}
+
+// queueMiddleware increments eventsQueued once per event, on the
+// Queue-time pass only - the chain runs again immediately before send (see
+// preSend), and that pass would otherwise double-count every event.
+// eventsSent, sendDuration, and storeDepth are recorded directly by the
+// client's send and store bookkeeping via recordSent/recordStoreDepth.
+func (m *metrics) queueMiddleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, e *event.Event) error {
+			if !isPreSend(ctx) {
+				m.eventsQueued.Inc()
+			}
+
+			return next(ctx, e)
+		}
+	}
+}
+
+func (m *metrics) recordSent(d time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.eventsSent.Inc()
+	m.sendDuration.Observe(d.Seconds())
+}
+
+func (m *metrics) recordStoreDepth(depth int) {
+	if m == nil {
+		return
+	}
+
+	m.storeDepth.Set(float64(depth))
+}
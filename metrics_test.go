@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blushft/jitsuclient/event"
+	"github.com/blushft/jitsuclient/event/events"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func counterValue(t *testing.T, c prometheus.Counter) float64 {
+	t.Helper()
+
+	var m dto.Metric
+	if err := c.Write(&m); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	return m.GetCounter().GetValue()
+}
+
+// TestMetricsQueueMiddlewareChargesOnlyQueuePass locks in the chunk0-5 fix
+// (4ebbefe): jitsu_events_queued_total must only increment on the initial
+// Queue-time pass, not again when the chain re-runs immediately before
+// send.
+func TestMetricsQueueMiddlewareChargesOnlyQueuePass(t *testing.T) {
+	m := newMetrics(prometheus.NewRegistry())
+	h := chain([]Middleware{m.queueMiddleware()}, func(context.Context, *event.Event) error {
+		return nil
+	})
+
+	evt := events.Track("e1")
+
+	if err := h(context.Background(), evt); err != nil {
+		t.Fatalf("queue-time pass: %v", err)
+	}
+	if err := h(withPreSend(context.Background()), evt); err != nil {
+		t.Fatalf("pre-send pass: %v", err)
+	}
+
+	if got := counterValue(t, m.eventsQueued); got != 1 {
+		t.Fatalf("eventsQueued = %v, want 1 (pre-send pass must not double-count)", got)
+	}
+}
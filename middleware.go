@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+
+	"github.com/blushft/jitsuclient/event"
+)
+
+// Handler processes a single event. It's the terminal operation wrapped by
+// a chain of Middleware.
+type Handler func(ctx context.Context, e *event.Event) error
+
+// Middleware wraps a Handler with cross-cutting behavior - PII redaction,
+// sampling, rate limiting, enrichment, routing/filtering - without forking
+// the client. An error returned by a Middleware (or the terminal Handler)
+// short-circuits the chain and is surfaced to the caller as the event being
+// dropped.
+//
+// The chain runs twice per event: once when it's queued, and again
+// immediately before it's sent (see preSend). A Middleware that consumes a
+// limited resource per call - a rate limiter's token bucket, a metrics
+// counter - should call isPreSend(ctx) and skip that accounting on the
+// second pass, or it'll be charged twice for a single event.
+type Middleware func(next Handler) Handler
+
+// preSendCtxKey marks a context as running the post-store, immediately
+// before dispatch. isPreSend reports it so stateful Middleware can tell
+// this pass apart from the initial Queue-time one.
+type preSendCtxKey struct{}
+
+func withPreSend(ctx context.Context) context.Context {
+	return context.WithValue(ctx, preSendCtxKey{}, true)
+}
+
+// isPreSend reports whether ctx is running the post-store, pre-send
+// middleware pass (see preSend) rather than the initial Queue-time pass.
+func isPreSend(ctx context.Context) bool {
+	v, _ := ctx.Value(preSendCtxKey{}).(bool)
+	return v
+}
+
+// WithMiddleware appends mw, in order, to the chain run on every event: once
+// when it's queued, and again immediately before it's sent.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(o *Options) {
+		o.Middleware = append(o.Middleware, mw...)
+	}
+}
+
+// chain composes mws around final, in registration order: the first
+// registered middleware is the outermost wrapper and sees the event first.
+func chain(mws []Middleware, final Handler) Handler {
+	h := final
+
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+
+	return h
+}
+
+// runMiddleware runs e through the configured middleware chain. A nil
+// error means the event should proceed as normal; any other error means it
+// should be dropped at this stage.
+func (t *Client) runMiddleware(ctx context.Context, e *event.Event) error {
+	if len(t.options.Middleware) == 0 {
+		return nil
+	}
+
+	h := chain(t.options.Middleware, func(context.Context, *event.Event) error {
+		return nil
+	})
+
+	return h(ctx, e)
+}
+
+// preSend re-runs the middleware chain immediately before dispatch, acting
+// as the "before send (post-store)" hook. It returns false if the event was
+// dropped - and has already been removed from the store - or true if it
+// should proceed to send, in which case e.Event is updated with whatever
+// the chain mutated (redaction, enrichment, ...) so send actually transmits
+// the post-middleware event rather than the pre-store copy.
+func (t *Client) preSend(ctx context.Context, e *StoreEvent) bool {
+	if len(t.options.Middleware) == 0 {
+		return true
+	}
+
+	evt, perr := event.Parse(e.Event)
+	if perr != nil {
+		return true
+	}
+
+	if err := t.runMiddleware(withPreSend(ctx), evt); err != nil {
+		if rmErr := t.store.Remove(e); rmErr != nil {
+			t.options.Logger.Errorf("error removing filtered event: %v", rmErr)
+		}
+
+		return false
+	}
+
+	e.Event = evt.Bytes()
+
+	return true
+}
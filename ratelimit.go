@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/blushft/jitsuclient/event"
+)
+
+var errRateLimited = errors.New("event rate limited")
+
+// tokenBucket is a minimal token-bucket limiter: it refills at rate tokens
+// per second, up to burst, and allow reports whether a token was available.
+type tokenBucket struct {
+	mu       sync.Mutex
+	rate     float64
+	burst    float64
+	tokens   float64
+	lastFill time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		rate:     rate,
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		lastFill: time.Now(),
+	}
+}
+
+func (b *tokenBucket) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens += b.rate * now.Sub(b.lastFill).Seconds()
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.lastFill = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+
+	return true
+}
+
+// NewRateLimitMiddleware drops events once more than rate-per-second
+// (bursting up to burst) have passed through it. The token bucket is only
+// charged on the Queue-time pass - the chain runs again immediately before
+// send (see preSend), and an event that already consumed a token to get
+// queued shouldn't consume a second one just to be delivered.
+func NewRateLimitMiddleware(rate float64, burst int) Middleware {
+	bucket := newTokenBucket(rate, burst)
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, e *event.Event) error {
+			if !isPreSend(ctx) && !bucket.allow() {
+				return errRateLimited
+			}
+
+			return next(ctx, e)
+		}
+	}
+}
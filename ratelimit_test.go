@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blushft/jitsuclient/event"
+	"github.com/blushft/jitsuclient/event/events"
+)
+
+// TestRateLimitMiddlewareChargesOnlyQueuePass locks in the chunk0-5 fix
+// (4ebbefe): the second, pre-send pass of the chain must not spend a
+// second token for an event that already paid for one at Queue time, and a
+// genuinely new queue-time event must still be limited normally.
+func TestRateLimitMiddlewareChargesOnlyQueuePass(t *testing.T) {
+	h := chain([]Middleware{NewRateLimitMiddleware(0, 1)}, func(context.Context, *event.Event) error {
+		return nil
+	})
+
+	evt := events.Track("e1")
+
+	if err := h(context.Background(), evt); err != nil {
+		t.Fatalf("first queue-time pass: got %v, want nil (burst token available)", err)
+	}
+
+	if err := h(withPreSend(context.Background()), evt); err != nil {
+		t.Fatalf("pre-send pass: got %v, want nil (should not spend a second token)", err)
+	}
+
+	if err := h(context.Background(), events.Track("e2")); err != errRateLimited {
+		t.Fatalf("second queue-time pass: got %v, want errRateLimited (burst exhausted, no refill)", err)
+	}
+}
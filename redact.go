@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/blushft/jitsuclient/event"
+)
+
+// NewRedactMiddleware returns a Middleware that zeroes exported fields of
+// every contexts struct attached to the event (contexts.User, .Group, ...)
+// that aren't in allow (when allow is non-empty) or that are in deny.
+// Field names are the struct's exported Go field names.
+func NewRedactMiddleware(allow, deny []string) Middleware {
+	allowed := toFieldSet(allow)
+	denied := toFieldSet(deny)
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, e *event.Event) error {
+			for _, c := range e.Contexts() {
+				redactFields(c, allowed, denied)
+			}
+
+			return next(ctx, e)
+		}
+	}
+}
+
+func toFieldSet(fields []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(fields))
+	for _, f := range fields {
+		set[f] = struct{}{}
+	}
+
+	return set
+}
+
+// redactFields zeroes exported fields of the struct pointed to by v that
+// aren't allowed or that are denied. Non-pointer or non-struct values are
+// left untouched.
+func redactFields(v interface{}, allow, deny map[string]struct{}) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return
+	}
+
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return
+	}
+
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		name := rt.Field(i).Name
+		if name == "" || name[0] < 'A' || name[0] > 'Z' {
+			continue
+		}
+
+		_, isAllowed := allow[name]
+		_, isDenied := deny[name]
+
+		if (len(allow) > 0 && !isAllowed) || isDenied {
+			field := rv.Field(i)
+			if field.CanSet() {
+				field.Set(reflect.Zero(field.Type()))
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package client
+
+import "testing"
+
+// redactTarget stands in for a contexts struct (contexts.User, ...) without
+// depending on the event/contexts package directly - redactFields only
+// cares about exported field names via reflection.
+type redactTarget struct {
+	Name     string
+	Email    string
+	internal string
+}
+
+func TestRedactFieldsDenyZeroesOnlyDenied(t *testing.T) {
+	v := &redactTarget{Name: "ann", Email: "ann@example.com", internal: "kept"}
+
+	redactFields(v, toFieldSet(nil), toFieldSet([]string{"Email"}))
+
+	if v.Name != "ann" {
+		t.Errorf("Name = %q, want unchanged", v.Name)
+	}
+	if v.Email != "" {
+		t.Errorf("Email = %q, want zeroed", v.Email)
+	}
+	if v.internal != "kept" {
+		t.Errorf("internal = %q, want untouched (unexported)", v.internal)
+	}
+}
+
+func TestRedactFieldsAllowZeroesEverythingElse(t *testing.T) {
+	v := &redactTarget{Name: "ann", Email: "ann@example.com"}
+
+	redactFields(v, toFieldSet([]string{"Name"}), toFieldSet(nil))
+
+	if v.Name != "ann" {
+		t.Errorf("Name = %q, want unchanged (allowed)", v.Name)
+	}
+	if v.Email != "" {
+		t.Errorf("Email = %q, want zeroed (not in allow)", v.Email)
+	}
+}
+
+func TestRedactFieldsDenyWinsOverAllow(t *testing.T) {
+	v := &redactTarget{Name: "ann", Email: "ann@example.com"}
+
+	redactFields(v, toFieldSet([]string{"Name", "Email"}), toFieldSet([]string{"Email"}))
+
+	if v.Name != "ann" {
+		t.Errorf("Name = %q, want unchanged", v.Name)
+	}
+	if v.Email != "" {
+		t.Errorf("Email = %q, want zeroed (denied even though also allowed)", v.Email)
+	}
+}
+
+func TestRedactFieldsIgnoresNonPointer(t *testing.T) {
+	v := redactTarget{Name: "ann", Email: "ann@example.com"}
+
+	redactFields(v, toFieldSet(nil), toFieldSet([]string{"Email"}))
+
+	if v.Email != "ann@example.com" {
+		t.Errorf("Email = %q, want untouched for a non-pointer value", v.Email)
+	}
+}
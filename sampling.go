@@ -0,0 +1,32 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+
+	"github.com/blushft/jitsuclient/event"
+)
+
+var errSampledOut = errors.New("event sampled out")
+
+// NewSamplingMiddleware deterministically samples events: an event passes
+// if hash(id) % 100 < rate*100. Hashing the ID (rather than rolling dice
+// per call) means a given event always samples the same way, and related
+// events sharing an ID sample together.
+func NewSamplingMiddleware(rate float64) Middleware {
+	threshold := uint32(rate * 100)
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, e *event.Event) error {
+			h := fnv.New32a()
+			_, _ = h.Write([]byte(e.ID))
+
+			if h.Sum32()%100 >= threshold {
+				return errSampledOut
+			}
+
+			return next(ctx, e)
+		}
+	}
+}
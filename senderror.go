@@ -0,0 +1,73 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// SendError carries the HTTP status code (if any) for a failed send so
+// sendFailed can distinguish transient failures worth retrying from ones
+// that never will succeed.
+type SendError struct {
+	StatusCode int
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *SendError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("send failed with status code %d: %v", e.StatusCode, e.Err)
+	}
+
+	return fmt.Sprintf("send failed with status code %d", e.StatusCode)
+}
+
+func (e *SendError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether the failure is transient: network errors,
+// 5xx responses, 429 (rate limited), and 408 (request timeout). Any other
+// 4xx is treated as permanent - retrying won't fix a malformed request or
+// an auth failure.
+func (e *SendError) Retryable() bool {
+	if e.StatusCode == 0 {
+		return true
+	}
+
+	if e.StatusCode == 408 || e.StatusCode == 429 {
+		return true
+	}
+
+	return e.StatusCode >= 500
+}
+
+// parseRetryAfter parses an HTTP Retry-After header value, either a
+// delay-seconds integer or an HTTP-date (the two forms RFC 7231 allows).
+// Unparseable values are ignored; 0 means no hint was present.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+
+		return time.Duration(secs) * time.Second
+	}
+
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0
+	}
+
+	if d := time.Until(t); d > 0 {
+		return d
+	}
+
+	return 0
+}
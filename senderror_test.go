@@ -0,0 +1,73 @@
+package client
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSendErrorRetryable(t *testing.T) {
+	cases := []struct {
+		name       string
+		statusCode int
+		want       bool
+	}{
+		{"no status (network error)", 0, true},
+		{"408 request timeout", 408, true},
+		{"429 rate limited", 429, true},
+		{"500 internal server error", 500, true},
+		{"503 service unavailable", 503, true},
+		{"400 bad request", 400, false},
+		{"401 unauthorized", 401, false},
+		{"404 not found", 404, false},
+		{"422 unprocessable entity", 422, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := &SendError{StatusCode: tc.statusCode}
+			if got := e.Retryable(); got != tc.want {
+				t.Errorf("Retryable() with status %d = %v, want %v", tc.statusCode, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfterSeconds(t *testing.T) {
+	cases := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"", 0},
+		{"120", 120 * time.Second},
+		{"0", 0},
+		{"-5", 0},
+		{"not-a-number-or-date", 0},
+	}
+
+	for _, tc := range cases {
+		if got := parseRetryAfter(tc.in); got != tc.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestParseRetryAfterHTTPDate(t *testing.T) {
+	future := time.Now().Add(2 * time.Minute).Truncate(time.Second)
+
+	got := parseRetryAfter(future.UTC().Format(http.TimeFormat))
+
+	// Allow a little slack for the time elapsed between formatting future
+	// above and parseRetryAfter calling time.Now() internally.
+	if got <= 0 || got > 2*time.Minute+time.Second {
+		t.Fatalf("parseRetryAfter(HTTP-date) = %v, want roughly 2m", got)
+	}
+}
+
+func TestParseRetryAfterPastHTTPDate(t *testing.T) {
+	past := time.Now().Add(-1 * time.Hour)
+
+	if got := parseRetryAfter(past.UTC().Format(http.TimeFormat)); got != 0 {
+		t.Fatalf("parseRetryAfter(past HTTP-date) = %v, want 0", got)
+	}
+}
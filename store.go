@@ -0,0 +1,186 @@
+package client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/blushft/jitsuclient/event"
+)
+
+// StoreEvent wraps a serialized event with the bookkeeping the retry path
+// needs: whether it has been attempted, how many times, and when.
+type StoreEvent struct {
+	ID          string
+	Event       []byte
+	Attempted   bool
+	Attempts    int
+	LastAttempt time.Time
+
+	// NextAttempt is the earliest time a failed event should be retried,
+	// computed by the backoff policy in sendFailed. Zero means the event
+	// has never failed and is eligible immediately.
+	NextAttempt time.Time
+}
+
+// Store persists queued events between the time they're accepted by Queue
+// and the time they're successfully sent (or given up on).
+type Store interface {
+	Set(e *event.Event) error
+	Update(e *StoreEvent) error
+	Remove(e *StoreEvent) error
+	GetAll() ([]*StoreEvent, error)
+	Count() int
+
+	// Bytes returns the cumulative serialized size, in bytes, of every
+	// event currently held in the store. The run loop uses it to flush
+	// early under WithFlushBytes.
+	Bytes() int64
+
+	// Iterate calls fn for each stored event in an implementation-defined
+	// order, stopping early if fn returns false. It lets callers (such as
+	// emit/emitBulk) scan a store without loading every event into memory
+	// at once.
+	Iterate(fn func(*StoreEvent) bool) error
+
+	// Since returns the events last attempted at or after t, in an
+	// implementation-defined order.
+	Since(t time.Time) ([]*StoreEvent, error)
+}
+
+// memStore is an in-memory, non-durable Store. Events queued between
+// process restarts are lost.
+type memStore struct {
+	mu     sync.RWMutex
+	events map[string]*StoreEvent
+
+	// bytes mirrors the cumulative size Bytes() would otherwise recompute
+	// by scanning events on every call - kept up to date here instead,
+	// since the run loop calls Bytes() once per enqueued event under
+	// WithFlushBytes.
+	bytes int64
+}
+
+// NewMemStore returns a Store backed by an in-memory map.
+func NewMemStore() (Store, error) {
+	return &memStore{
+		events: make(map[string]*StoreEvent),
+	}, nil
+}
+
+func (m *memStore) Set(e *event.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	se := &StoreEvent{ID: e.ID, Event: e.Bytes()}
+
+	if old, ok := m.events[se.ID]; ok {
+		m.bytes -= int64(len(old.Event))
+	}
+	m.bytes += int64(len(se.Event))
+
+	m.events[se.ID] = se
+
+	return nil
+}
+
+func (m *memStore) Update(e *StoreEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if old, ok := m.events[e.ID]; ok {
+		m.bytes -= int64(len(old.Event))
+	}
+	m.bytes += int64(len(e.Event))
+
+	m.events[e.ID] = e
+
+	return nil
+}
+
+func (m *memStore) Remove(e *StoreEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if old, ok := m.events[e.ID]; ok {
+		m.bytes -= int64(len(old.Event))
+		delete(m.events, e.ID)
+	}
+
+	return nil
+}
+
+func (m *memStore) GetAll() ([]*StoreEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	evts := make([]*StoreEvent, 0, len(m.events))
+	for _, e := range m.events {
+		evts = append(evts, e)
+	}
+
+	return evts, nil
+}
+
+func (m *memStore) Count() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.events)
+}
+
+func (m *memStore) Bytes() int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.bytes
+}
+
+func (m *memStore) Iterate(fn func(*StoreEvent) bool) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, e := range m.events {
+		if !fn(e) {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (m *memStore) Since(t time.Time) ([]*StoreEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var evts []*StoreEvent
+	for _, e := range m.events {
+		if e.LastAttempt.Equal(t) || e.LastAttempt.After(t) {
+			evts = append(evts, e)
+		}
+	}
+
+	return evts, nil
+}
+
+// StoreFactory lazily constructs a Store. It exists alongside WithStore so
+// stores that need resources only available after other options have been
+// applied (a file path, a DSN) can be created at New time instead of by the
+// caller.
+type StoreFactory func(o *Options) (Store, error)
+
+// WithStore sets the Store used to persist queued events. Defaults to
+// NewMemStore.
+func WithStore(s Store) Option {
+	return func(o *Options) {
+		o.Store = s
+	}
+}
+
+// WithStoreFactory sets a StoreFactory used to construct the Store at New
+// time, once the rest of the options are known. It takes precedence over
+// WithStore.
+func WithStoreFactory(f StoreFactory) Option {
+	return func(o *Options) {
+		o.StoreFactory = f
+	}
+}
@@ -0,0 +1,275 @@
+//go:build bolt
+
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/blushft/jitsuclient/event"
+
+	"go.etcd.io/bbolt"
+)
+
+var (
+	boltBucketPending   = []byte("pending")
+	boltBucketAttempted = []byte("attempted")
+)
+
+// boltStore is a BoltDB-backed, durable Store: events survive process
+// restarts in a single embedded file, split into a bucket for events that
+// haven't been attempted yet and one for events that have (so Attempts and
+// LastAttempt are preserved across the move).
+type boltStore struct {
+	db *bbolt.DB
+
+	// bytes mirrors the cumulative size Bytes() would otherwise recompute
+	// by scanning and JSON-unmarshaling every stored event on every call -
+	// kept up to date here instead, since the run loop calls Bytes() once
+	// per enqueued event under WithFlushBytes.
+	bytes int64
+}
+
+// NewBoltStore opens (creating if necessary) a BoltDB file at path and
+// returns a Store backed by it.
+func NewBoltStore(path string) (Store, error) {
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("error opening bolt store: %w", err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(boltBucketPending); err != nil {
+			return err
+		}
+
+		_, err := tx.CreateBucketIfNotExists(boltBucketAttempted)
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error initializing bolt store: %w", err)
+	}
+
+	b := &boltStore{db: db}
+	if err := b.initBytes(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error initializing bolt store: %w", err)
+	}
+
+	return b, nil
+}
+
+// initBytes seeds b.bytes from whatever's already on disk (a reopened
+// store from a prior run) with the one scan this approach still needs;
+// every Set/Update/Remove afterward maintains it incrementally.
+func (b *boltStore) initBytes() error {
+	var n int64
+
+	if err := b.Iterate(func(e *StoreEvent) bool {
+		n += int64(len(e.Event))
+		return true
+	}); err != nil {
+		return err
+	}
+
+	atomic.StoreInt64(&b.bytes, n)
+
+	return nil
+}
+
+func (b *boltStore) bucketFor(e *StoreEvent) []byte {
+	if e.Attempted {
+		return boltBucketAttempted
+	}
+
+	return boltBucketPending
+}
+
+// boltStoredLen returns the length of the event stored under id across both
+// buckets, or 0 if it isn't present in either.
+func boltStoredLen(tx *bbolt.Tx, id string) (int64, error) {
+	for _, bucket := range [][]byte{boltBucketPending, boltBucketAttempted} {
+		v := tx.Bucket(bucket).Get([]byte(id))
+		if v == nil {
+			continue
+		}
+
+		var se StoreEvent
+		if err := json.Unmarshal(v, &se); err != nil {
+			return 0, err
+		}
+
+		return int64(len(se.Event)), nil
+	}
+
+	return 0, nil
+}
+
+func (b *boltStore) Set(e *event.Event) error {
+	se := &StoreEvent{ID: e.ID, Event: e.Bytes()}
+	delta := int64(len(se.Event))
+
+	err := b.db.Update(func(tx *bbolt.Tx) error {
+		old, err := boltStoredLen(tx, se.ID)
+		if err != nil {
+			return err
+		}
+		delta -= old
+
+		return putStoreEvent(tx.Bucket(boltBucketPending), se)
+	})
+	if err != nil {
+		return err
+	}
+
+	atomic.AddInt64(&b.bytes, delta)
+
+	return nil
+}
+
+func (b *boltStore) Update(e *StoreEvent) error {
+	delta := int64(len(e.Event))
+
+	err := b.db.Update(func(tx *bbolt.Tx) error {
+		old, err := boltStoredLen(tx, e.ID)
+		if err != nil {
+			return err
+		}
+		delta -= old
+
+		if err := tx.Bucket(boltBucketPending).Delete([]byte(e.ID)); err != nil {
+			return err
+		}
+
+		if err := tx.Bucket(boltBucketAttempted).Delete([]byte(e.ID)); err != nil {
+			return err
+		}
+
+		return putStoreEvent(tx.Bucket(b.bucketFor(e)), e)
+	})
+	if err != nil {
+		return err
+	}
+
+	atomic.AddInt64(&b.bytes, delta)
+
+	return nil
+}
+
+func (b *boltStore) Remove(e *StoreEvent) error {
+	var removed int64
+
+	err := b.db.Update(func(tx *bbolt.Tx) error {
+		old, err := boltStoredLen(tx, e.ID)
+		if err != nil {
+			return err
+		}
+		removed = old
+
+		if err := tx.Bucket(boltBucketPending).Delete([]byte(e.ID)); err != nil {
+			return err
+		}
+
+		return tx.Bucket(boltBucketAttempted).Delete([]byte(e.ID))
+	})
+	if err != nil {
+		return err
+	}
+
+	atomic.AddInt64(&b.bytes, -removed)
+
+	return nil
+}
+
+func (b *boltStore) GetAll() ([]*StoreEvent, error) {
+	var evts []*StoreEvent
+
+	err := b.Iterate(func(e *StoreEvent) bool {
+		evts = append(evts, e)
+		return true
+	})
+
+	return evts, err
+}
+
+func (b *boltStore) Count() int {
+	count := 0
+
+	_ = b.db.View(func(tx *bbolt.Tx) error {
+		count += tx.Bucket(boltBucketPending).Stats().KeyN
+		count += tx.Bucket(boltBucketAttempted).Stats().KeyN
+
+		return nil
+	})
+
+	return count
+}
+
+func (b *boltStore) Bytes() int64 {
+	return atomic.LoadInt64(&b.bytes)
+}
+
+func (b *boltStore) Iterate(fn func(*StoreEvent) bool) error {
+	return b.db.View(func(tx *bbolt.Tx) error {
+		for _, bucket := range [][]byte{boltBucketPending, boltBucketAttempted} {
+			stop := false
+
+			err := tx.Bucket(bucket).ForEach(func(k, v []byte) error {
+				if stop {
+					return nil
+				}
+
+				var se StoreEvent
+				if err := json.Unmarshal(v, &se); err != nil {
+					return err
+				}
+
+				if !fn(&se) {
+					stop = true
+				}
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			if stop {
+				return nil
+			}
+		}
+
+		return nil
+	})
+}
+
+func (b *boltStore) Since(t time.Time) ([]*StoreEvent, error) {
+	var evts []*StoreEvent
+
+	err := b.Iterate(func(e *StoreEvent) bool {
+		if e.LastAttempt.Equal(t) || e.LastAttempt.After(t) {
+			evts = append(evts, e)
+		}
+
+		return true
+	})
+
+	return evts, err
+}
+
+// Close releases the underlying BoltDB file handle.
+func (b *boltStore) Close() error {
+	return b.db.Close()
+}
+
+func putStoreEvent(bucket *bbolt.Bucket, e *StoreEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	return bucket.Put([]byte(e.ID), data)
+}
@@ -0,0 +1,12 @@
+//go:build !bolt
+
+package client
+
+import "errors"
+
+// NewBoltStore is unavailable: this binary was built without the "bolt"
+// build tag, so the go.etcd.io/bbolt dependency isn't linked in. Build
+// with -tags bolt to use a BoltDB-backed Store.
+func NewBoltStore(path string) (Store, error) {
+	return nil, errors.New("jitsuclient: NewBoltStore requires building with -tags bolt")
+}
@@ -0,0 +1,41 @@
+//go:build bolt
+
+package client
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestBoltStoreUpdateRemoveGetAll(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.bolt")
+
+	s, err := NewBoltStore(path)
+	if err != nil {
+		t.Fatalf("NewBoltStore: %v", err)
+	}
+	defer s.(*boltStore).Close()
+
+	if err := s.Update(&StoreEvent{ID: "a", Event: []byte("event-a")}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := s.Update(&StoreEvent{ID: "b", Event: []byte("event-b"), Attempted: true}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	if n := s.Count(); n != 2 {
+		t.Fatalf("Count() = %d, want 2", n)
+	}
+
+	if err := s.Remove(&StoreEvent{ID: "a"}); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+
+	all, err := s.GetAll()
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(all) != 1 || all[0].ID != "b" {
+		t.Fatalf("GetAll() = %+v, want just event b", all)
+	}
+}
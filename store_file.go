@@ -0,0 +1,252 @@
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/blushft/jitsuclient/event"
+)
+
+// fileStore is a durable Store backed by an append-only JSONL log. Every
+// Set/Update/Remove appends a record; Remove and a since-superseded Update
+// leave the old record as dead weight, which compact() reclaims by
+// rewriting the log with only the live events.
+type fileStore struct {
+	mu   sync.Mutex
+	path string
+
+	events map[string]*StoreEvent
+
+	// bytes mirrors the cumulative size Bytes() would otherwise recompute
+	// by scanning events on every call - kept up to date here instead,
+	// since the run loop calls Bytes() once per enqueued event under
+	// WithFlushBytes.
+	bytes int64
+
+	writes       int
+	compactEvery int
+}
+
+type fileStoreRecord struct {
+	Event   *StoreEvent `json:"event"`
+	Removed bool        `json:"removed,omitempty"`
+}
+
+// NewFileStore opens (creating and replaying if necessary) an append-only
+// file store at path. compactEvery controls how many writes accumulate
+// before the log is compacted; pass 0 to disable automatic compaction.
+func NewFileStore(path string, compactEvery int) (Store, error) {
+	fs := &fileStore{
+		path:         path,
+		events:       make(map[string]*StoreEvent),
+		compactEvery: compactEvery,
+	}
+
+	if err := fs.replay(); err != nil {
+		return nil, fmt.Errorf("error replaying file store: %w", err)
+	}
+
+	return fs, nil
+}
+
+func (f *fileStore) replay() error {
+	file, err := os.OpenFile(f.path, os.O_RDONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var rec fileStoreRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return err
+		}
+
+		if rec.Removed {
+			if old, ok := f.events[rec.Event.ID]; ok {
+				f.bytes -= int64(len(old.Event))
+				delete(f.events, rec.Event.ID)
+			}
+			continue
+		}
+
+		if old, ok := f.events[rec.Event.ID]; ok {
+			f.bytes -= int64(len(old.Event))
+		}
+		f.bytes += int64(len(rec.Event.Event))
+
+		f.events[rec.Event.ID] = rec.Event
+	}
+
+	return scanner.Err()
+}
+
+func (f *fileStore) append(rec fileStoreRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+
+	f.writes++
+	if f.compactEvery > 0 && f.writes >= f.compactEvery {
+		return f.compactLocked()
+	}
+
+	return nil
+}
+
+// compactLocked rewrites the log with only the events currently held in
+// memory, discarding accumulated removed/superseded records. Callers must
+// hold f.mu.
+func (f *fileStore) compactLocked() error {
+	tmp := f.path + ".compact"
+
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range f.events {
+		data, err := json.Marshal(fileStoreRecord{Event: e})
+		if err != nil {
+			file.Close()
+			return err
+		}
+
+		if _, err := file.Write(append(data, '\n')); err != nil {
+			file.Close()
+			return err
+		}
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, f.path); err != nil {
+		return err
+	}
+
+	f.writes = 0
+
+	return nil
+}
+
+func (f *fileStore) Set(e *event.Event) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	se := &StoreEvent{ID: e.ID, Event: e.Bytes()}
+
+	if old, ok := f.events[se.ID]; ok {
+		f.bytes -= int64(len(old.Event))
+	}
+	f.bytes += int64(len(se.Event))
+
+	f.events[se.ID] = se
+
+	return f.append(fileStoreRecord{Event: se})
+}
+
+func (f *fileStore) Update(e *StoreEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if old, ok := f.events[e.ID]; ok {
+		f.bytes -= int64(len(old.Event))
+	}
+	f.bytes += int64(len(e.Event))
+
+	f.events[e.ID] = e
+
+	return f.append(fileStoreRecord{Event: e})
+}
+
+func (f *fileStore) Remove(e *StoreEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if old, ok := f.events[e.ID]; ok {
+		f.bytes -= int64(len(old.Event))
+		delete(f.events, e.ID)
+	}
+
+	return f.append(fileStoreRecord{Event: e, Removed: true})
+}
+
+func (f *fileStore) GetAll() ([]*StoreEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	evts := make([]*StoreEvent, 0, len(f.events))
+	for _, e := range f.events {
+		evts = append(evts, e)
+	}
+
+	return evts, nil
+}
+
+func (f *fileStore) Count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.events)
+}
+
+func (f *fileStore) Bytes() int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.bytes
+}
+
+func (f *fileStore) Iterate(fn func(*StoreEvent) bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, e := range f.events {
+		if !fn(e) {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (f *fileStore) Since(t time.Time) ([]*StoreEvent, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var evts []*StoreEvent
+	for _, e := range f.events {
+		if e.LastAttempt.Equal(t) || e.LastAttempt.After(t) {
+			evts = append(evts, e)
+		}
+	}
+
+	return evts, nil
+}
+
+// Compact forces an immediate rewrite of the log file, discarding
+// accumulated removed/superseded records regardless of compactEvery.
+func (f *fileStore) Compact() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.compactLocked()
+}
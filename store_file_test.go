@@ -0,0 +1,108 @@
+package client
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileStorePersistsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	s, err := NewFileStore(path, 0)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+
+	if err := s.Update(&StoreEvent{ID: "a", Event: []byte("event-a")}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := s.Update(&StoreEvent{ID: "b", Event: []byte("event-b")}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := s.Remove(&StoreEvent{ID: "b"}); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+
+	if n := s.Count(); n != 1 {
+		t.Fatalf("Count() = %d, want 1", n)
+	}
+
+	reopened, err := NewFileStore(path, 0)
+	if err != nil {
+		t.Fatalf("NewFileStore (reopen): %v", err)
+	}
+
+	if n := reopened.Count(); n != 1 {
+		t.Fatalf("Count() after reopen = %d, want 1", n)
+	}
+
+	all, err := reopened.GetAll()
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(all) != 1 || all[0].ID != "a" {
+		t.Fatalf("GetAll() after reopen = %+v, want just event a", all)
+	}
+}
+
+func TestFileStoreBytesAndSince(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	s, err := NewFileStore(path, 0)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+
+	now := time.Now()
+	old := now.Add(-time.Hour)
+
+	if err := s.Update(&StoreEvent{ID: "recent", Event: []byte("1234"), LastAttempt: now}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := s.Update(&StoreEvent{ID: "stale", Event: []byte("12"), LastAttempt: old}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	if want, got := int64(6), s.Bytes(); got != want {
+		t.Errorf("Bytes() = %d, want %d", got, want)
+	}
+
+	since, err := s.Since(now.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("Since: %v", err)
+	}
+	if len(since) != 1 || since[0].ID != "recent" {
+		t.Fatalf("Since() = %+v, want just the recent event", since)
+	}
+}
+
+func TestFileStoreCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	s, err := NewFileStore(path, 2)
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+
+	if err := s.Update(&StoreEvent{ID: "a", Event: []byte("a")}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := s.Remove(&StoreEvent{ID: "a"}); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	// compactEvery=2 should have rewritten the log by now, dropping the
+	// dead record for the removed event.
+	if err := s.Update(&StoreEvent{ID: "b", Event: []byte("b")}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	reopened, err := NewFileStore(path, 0)
+	if err != nil {
+		t.Fatalf("NewFileStore (reopen): %v", err)
+	}
+
+	if n := reopened.Count(); n != 1 {
+		t.Fatalf("Count() after reopen = %d, want 1", n)
+	}
+}
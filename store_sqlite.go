@@ -0,0 +1,179 @@
+//go:build sqlite
+
+package client
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/blushft/jitsuclient/event"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS events (
+	id           TEXT PRIMARY KEY,
+	event        BLOB NOT NULL,
+	attempted    BOOLEAN NOT NULL DEFAULT 0,
+	attempts     INTEGER NOT NULL DEFAULT 0,
+	last_attempt DATETIME,
+	next_attempt DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_events_attempted_last_attempt ON events (attempted, last_attempt);
+`
+
+// sqliteStore is a database/sql-backed, durable Store. It's a better fit
+// than boltStore when events need to be inspected or retried with SQL (the
+// attempted/last_attempt index exists for exactly that).
+type sqliteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens (creating and migrating if necessary) a SQLite
+// database at path and returns a Store backed by it.
+func NewSQLiteStore(path string) (Store, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening sqlite store: %w", err)
+	}
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return nil, fmt.Errorf("error migrating sqlite store: %w", err)
+	}
+
+	return &sqliteStore{db: db}, nil
+}
+
+func (s *sqliteStore) Set(e *event.Event) error {
+	_, err := s.db.Exec(
+		`INSERT OR REPLACE INTO events (id, event, attempted, attempts, last_attempt, next_attempt) VALUES (?, ?, 0, 0, NULL, NULL)`,
+		e.ID, e.Bytes(),
+	)
+
+	return err
+}
+
+func (s *sqliteStore) Update(e *StoreEvent) error {
+	_, err := s.db.Exec(
+		`UPDATE events SET attempted = ?, attempts = ?, last_attempt = ?, next_attempt = ? WHERE id = ?`,
+		e.Attempted, e.Attempts, e.LastAttempt, nullableTime(e.NextAttempt), e.ID,
+	)
+
+	return err
+}
+
+// nullableTime converts a possibly-zero time.Time into a value database/sql
+// can write as NULL - StoreEvent.NextAttempt is zero until an event's first
+// failed send, and a zero time.Time would otherwise round-trip as some
+// driver-specific non-NULL sentinel instead.
+func nullableTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t
+}
+
+func (s *sqliteStore) Remove(e *StoreEvent) error {
+	_, err := s.db.Exec(`DELETE FROM events WHERE id = ?`, e.ID)
+
+	return err
+}
+
+func (s *sqliteStore) GetAll() ([]*StoreEvent, error) {
+	return s.query(`SELECT id, event, attempted, attempts, last_attempt, next_attempt FROM events`)
+}
+
+func (s *sqliteStore) Count() int {
+	var count int
+
+	_ = s.db.QueryRow(`SELECT COUNT(*) FROM events`).Scan(&count)
+
+	return count
+}
+
+func (s *sqliteStore) Bytes() int64 {
+	var n int64
+
+	_ = s.db.QueryRow(`SELECT COALESCE(SUM(LENGTH(event)), 0) FROM events`).Scan(&n)
+
+	return n
+}
+
+func (s *sqliteStore) Iterate(fn func(*StoreEvent) bool) error {
+	rows, err := s.db.Query(`SELECT id, event, attempted, attempts, last_attempt, next_attempt FROM events`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e, err := scanStoreEvent(rows)
+		if err != nil {
+			return err
+		}
+
+		if !fn(e) {
+			break
+		}
+	}
+
+	return rows.Err()
+}
+
+func (s *sqliteStore) Since(t time.Time) ([]*StoreEvent, error) {
+	return s.query(
+		`SELECT id, event, attempted, attempts, last_attempt, next_attempt FROM events WHERE attempted = 1 AND last_attempt >= ? ORDER BY last_attempt DESC`,
+		t,
+	)
+}
+
+func (s *sqliteStore) query(q string, args ...interface{}) ([]*StoreEvent, error) {
+	rows, err := s.db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var evts []*StoreEvent
+	for rows.Next() {
+		e, err := scanStoreEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		evts = append(evts, e)
+	}
+
+	return evts, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanStoreEvent(row rowScanner) (*StoreEvent, error) {
+	var e StoreEvent
+	var lastAttempt, nextAttempt sql.NullTime
+
+	if err := row.Scan(&e.ID, &e.Event, &e.Attempted, &e.Attempts, &lastAttempt, &nextAttempt); err != nil {
+		return nil, err
+	}
+
+	if lastAttempt.Valid {
+		e.LastAttempt = lastAttempt.Time
+	}
+
+	if nextAttempt.Valid {
+		e.NextAttempt = nextAttempt.Time
+	}
+
+	return &e, nil
+}
+
+// Close releases the underlying database handle.
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
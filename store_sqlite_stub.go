@@ -0,0 +1,13 @@
+//go:build !sqlite
+
+package client
+
+import "errors"
+
+// NewSQLiteStore is unavailable: this binary was built without the
+// "sqlite" build tag, so the cgo-based github.com/mattn/go-sqlite3
+// dependency isn't linked in. Build with -tags sqlite to use a
+// SQLite-backed Store.
+func NewSQLiteStore(path string) (Store, error) {
+	return nil, errors.New("jitsuclient: NewSQLiteStore requires building with -tags sqlite")
+}
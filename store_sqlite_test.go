@@ -0,0 +1,93 @@
+//go:build sqlite
+
+package client
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLiteStoreUpdateRemoveGetAll(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.db")
+
+	s, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	ss := s.(*sqliteStore)
+	defer ss.Close()
+
+	// Update only ever UPDATEs an existing row (rows are created by Set,
+	// which needs an *event.Event we don't have in this package-local
+	// test), so seed the row directly.
+	if _, err := ss.db.Exec(
+		`INSERT INTO events (id, event, attempted, attempts, last_attempt) VALUES (?, ?, 0, 0, NULL)`,
+		"a", []byte("event-a"),
+	); err != nil {
+		t.Fatalf("seeding row: %v", err)
+	}
+
+	if n := s.Count(); n != 1 {
+		t.Fatalf("Count() = %d, want 1", n)
+	}
+	if want, got := int64(len("event-a")), s.Bytes(); got != want {
+		t.Fatalf("Bytes() = %d, want %d", got, want)
+	}
+
+	nextAttempt := time.Now().Add(30 * time.Second).Truncate(time.Second).UTC()
+
+	if err := s.Update(&StoreEvent{ID: "a", Attempted: true, Attempts: 1, NextAttempt: nextAttempt}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	all, err := s.GetAll()
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(all) != 1 || !all[0].Attempted || all[0].Attempts != 1 {
+		t.Fatalf("GetAll() = %+v, want one attempted event with Attempts=1", all)
+	}
+	if !all[0].NextAttempt.Equal(nextAttempt) {
+		t.Fatalf("GetAll()[0].NextAttempt = %v, want %v (NextAttempt must round-trip so backoff is honored)", all[0].NextAttempt, nextAttempt)
+	}
+
+	if err := s.Remove(&StoreEvent{ID: "a"}); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if n := s.Count(); n != 0 {
+		t.Fatalf("Count() after Remove = %d, want 0", n)
+	}
+}
+
+func TestSQLiteStoreUpdateClearsNextAttempt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.db")
+
+	s, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	ss := s.(*sqliteStore)
+	defer ss.Close()
+
+	if _, err := ss.db.Exec(
+		`INSERT INTO events (id, event, attempted, attempts, last_attempt, next_attempt) VALUES (?, ?, 1, 1, ?, ?)`,
+		"a", []byte("event-a"), time.Now(), time.Now().Add(time.Minute),
+	); err != nil {
+		t.Fatalf("seeding row: %v", err)
+	}
+
+	// An Update with a zero NextAttempt replaces the stored one - make sure
+	// that round-trips as NULL rather than some non-zero sentinel.
+	if err := s.Update(&StoreEvent{ID: "a", Attempted: true, Attempts: 1}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	all, err := s.GetAll()
+	if err != nil {
+		t.Fatalf("GetAll: %v", err)
+	}
+	if len(all) != 1 || !all[0].NextAttempt.IsZero() {
+		t.Fatalf("GetAll()[0].NextAttempt = %v, want the zero value", all[0].NextAttempt)
+	}
+}
@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// deadline bounds each HTTP round-trip made by the client under
+// SetDefaultTimeout. It exists mainly so sends have a single call site
+// (context) to derive a bounded context from, regardless of whether a
+// timeout is configured.
+type deadline struct{}
+
+func newDeadline() *deadline {
+	return &deadline{}
+}
+
+// context derives a child of parent that is canceled when parent is done,
+// when d elapses, or when the returned CancelFunc is called, whichever
+// comes first. A non-positive d disables the timer and parent is simply
+// wrapped for cancellation. Each call arms its own timer, so one slow send
+// can't cancel another concurrent send's deadline.
+func (dl *deadline) context(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(parent)
+	}
+
+	return context.WithTimeout(parent, d)
+}
+
+// SetDefaultTimeout bounds every HTTP round-trip made by the client in a
+// deadline. A value of zero (the default) disables the timeout.
+func SetDefaultTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		o.DefaultTimeout = d
+	}
+}